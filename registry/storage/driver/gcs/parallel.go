@@ -2,8 +2,10 @@ package gcs
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	dcontext "github.com/docker/distribution/context"
 	storagedriver "github.com/docker/distribution/registry/storage/driver"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/cloud"
@@ -11,16 +13,25 @@ import (
 	"io"
 	"net/url"
 	"sync"
+	"sync/atomic"
 )
 
 type chunk struct {
 	buf   []byte
 	start int64
+	// done, if non-nil, is closed by the worker once this chunk has been
+	// fully processed. Only Close sets it, on the final chunk, so it can
+	// block until the chunk is actually consumed before closing doneCh --
+	// otherwise a worker's select could pick the now-closed doneCh over the
+	// still-buffered chunk and exit leaving it stranded.
+	done chan struct{}
 }
 
 type parallelWriter struct {
 	driver      *driver
 	path        string
+	ctx         context.Context
+	cancel      context.CancelFunc
 	wg          *sync.WaitGroup
 	chunkCh     chan<- chunk
 	doneCh      chan struct{}
@@ -30,10 +41,37 @@ type parallelWriter struct {
 	totalSize   int64
 	closed      bool
 	cancelled   bool
+
+	errOnce sync.Once
+	errVal  atomic.Value
+}
+
+// storeErr records err as the writer's first error, if one hasn't already
+// been recorded, and cancels ctx so in-flight workers stop picking up new
+// chunks rather than running to completion against a doomed upload.
+func (pw *parallelWriter) storeErr(err error) {
+	if err == nil {
+		return
+	}
+	pw.errOnce.Do(func() {
+		pw.errVal.Store(err)
+		pw.cancel()
+	})
+}
+
+// Err returns the first error recorded by a worker, if any.
+func (pw *parallelWriter) Err() error {
+	if v := pw.errVal.Load(); v != nil {
+		return v.(error)
+	}
+	return nil
 }
 
 func (pw *parallelWriter) Write(p []byte) (n int, err error) {
 	for n = 0; n < len(p); {
+		if err := pw.Err(); err != nil {
+			return n, err
+		}
 		if pw.closed {
 			return n, fmt.Errorf("Wrote to closed writer") // TODO: better err?
 		}
@@ -47,11 +85,13 @@ func (pw *parallelWriter) Write(p []byte) (n int, err error) {
 		pw.offset += nn
 		pw.totalSize += int64(nn)
 		if pw.offset == cap(pw.buf) {
-			pw.chunkCh <- chunk{
-				start: pw.startOffset,
-				buf:   pw.buf,
+			select {
+			case pw.chunkCh <- chunk{start: pw.startOffset, buf: pw.buf}:
+			case <-pw.ctx.Done():
+				return n, pw.Err()
 			}
 			pw.buf = nil
+			pw.offset = 0
 		}
 	}
 	return n, nil
@@ -64,9 +104,19 @@ func (pw *parallelWriter) Close() error {
 	pw.closed = true
 
 	if pw.offset > 0 {
-		pw.chunkCh <- chunk{
-			start: pw.startOffset,
-			buf:   pw.buf[:pw.offset],
+		done := make(chan struct{})
+		select {
+		case pw.chunkCh <- chunk{start: pw.startOffset, buf: pw.buf[:pw.offset], done: done}:
+			// Wait for a worker to actually finish this chunk before closing
+			// doneCh below, so a worker can't race doneCh's close against the
+			// still-buffered chunk and exit without uploading it.
+			select {
+			case <-done:
+			case <-pw.ctx.Done():
+			}
+		case <-pw.ctx.Done():
+			// All workers have already exited; nothing will ever drain
+			// chunkCh, so fall through without blocking on the send.
 		}
 		pw.startOffset = pw.totalSize
 		pw.offset = 0
@@ -75,7 +125,7 @@ func (pw *parallelWriter) Close() error {
 
 	close(pw.doneCh)
 	pw.wg.Wait()
-	return nil
+	return pw.Err()
 }
 
 func (pw parallelWriter) Size() int64 {
@@ -137,6 +187,10 @@ func composeObjects(objects []*storage.Object) *composeRequest {
 func (pw *parallelWriter) Commit() error {
 	pw.Close()
 
+	if err := pw.Err(); err != nil {
+		return err
+	}
+
 	gcsContext := cloud.NewContext(dummyProjectID, pw.driver.client)
 
 	pathKey := pw.driver.pathToKey(pw.path)
@@ -166,13 +220,16 @@ func (pw *parallelWriter) Commit() error {
 
 type WriterFunc func(int64) (storagedriver.FileWriter, error)
 
-func NewParallelWriter(driver *driver, path string, writerFunc WriterFunc, workers int) *parallelWriter {
+func NewParallelWriter(ctx context.Context, driver *driver, path string, writerFunc WriterFunc, workers int) *parallelWriter {
 	wg := &sync.WaitGroup{}
 	chunkCh := make(chan chunk, 1)
 	doneCh := make(chan struct{})
+	ctx, cancel := context.WithCancel(ctx)
 
 	pw := &parallelWriter{
 		driver:  driver,
+		ctx:     ctx,
+		cancel:  cancel,
 		wg:      wg,
 		chunkCh: chunkCh,
 		doneCh:  doneCh,
@@ -186,18 +243,45 @@ func NewParallelWriter(driver *driver, path string, writerFunc WriterFunc, worke
 			for {
 				select {
 				case c := <-chunkCh:
+					// storeErr cancels ctx, which is enough to stop every
+					// worker (including this one) from picking up further
+					// chunks; it must NOT call pw.Cancel() itself, since
+					// Cancel->Close->wg.Wait() would deadlock this goroutine
+					// waiting on its own wg.Done(). Cleanup of any
+					// already-uploaded chunks is the caller's job once
+					// Commit/Close surfaces the error via pw.Err().
 					w, err := writerFunc(c.start)
 					if err != nil {
-						pw.Cancel()
+						pw.storeErr(err)
+						dcontext.GetLogger(ctx).WithError(err).Warnf("gcs: NewParallelWriter(%v): writerFunc(%v) failed", path, c.start)
+						if c.done != nil {
+							close(c.done)
+						}
+						continue
 					}
 					if _, err := io.Copy(w, bytes.NewReader(c.buf)); err != nil {
-						pw.Cancel()
+						pw.storeErr(err)
+						dcontext.GetLogger(ctx).WithError(err).Warnf("gcs: NewParallelWriter(%v): copying chunk at %v failed", path, c.start)
+						if c.done != nil {
+							close(c.done)
+						}
+						continue
 					}
 					if err := w.Commit(); err != nil {
-						pw.Cancel()
+						pw.storeErr(err)
+						dcontext.GetLogger(ctx).WithError(err).Warnf("gcs: NewParallelWriter(%v): committing chunk at %v failed", path, c.start)
+						if c.done != nil {
+							close(c.done)
+						}
+						continue
 					}
 					driver.pool.Put(c.buf[:cap(c.buf)])
+					if c.done != nil {
+						close(c.done)
+					}
 					continue
+				case <-ctx.Done():
+					return
 				case <-doneCh:
 					return
 				}
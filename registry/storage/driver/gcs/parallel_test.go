@@ -0,0 +1,150 @@
+package gcs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParallelWriterStoreErrKeepsFirstAndCancels(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pw := &parallelWriter{ctx: ctx, cancel: cancel}
+
+	first := fmt.Errorf("first failure")
+	second := fmt.Errorf("second failure")
+	pw.storeErr(first)
+	pw.storeErr(second)
+
+	if got := pw.Err(); got != first {
+		t.Fatalf("Err() = %v, want the first recorded error %v", got, first)
+	}
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatalf("storeErr did not cancel ctx")
+	}
+}
+
+func TestParallelWriterWriteReturnsStoredErrImmediately(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pw := &parallelWriter{ctx: ctx, cancel: cancel}
+
+	wantErr := fmt.Errorf("writer is doomed")
+	pw.storeErr(wantErr)
+
+	n, err := pw.Write([]byte("hello"))
+	if n != 0 || err != wantErr {
+		t.Fatalf("Write() = (%v, %v), want (0, %v)", n, err, wantErr)
+	}
+}
+
+func TestParallelWriterWriteUnblocksOnCtxDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	chunkCh := make(chan chunk) // unbuffered and never drained
+	pw := &parallelWriter{
+		ctx:     ctx,
+		cancel:  cancel,
+		chunkCh: chunkCh,
+		driver:  &driver{},
+	}
+	pw.buf = make([]byte, 0) // cap 0 so the very first byte fills it
+
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.Write([]byte("x"))
+	}()
+
+	select {
+	case <-done:
+	default:
+	}
+	<-done // Write must return via ctx.Done(), not block forever on chunkCh
+}
+
+// TestParallelWriterWriteResetsOffsetOnChunkBoundary guards against a panic
+// in Close(): if a Write call lands exactly on a chunk boundary, pw.buf is
+// cleared but pw.offset must be too, or Close() later slices a nil pw.buf
+// with a stale, non-zero offset.
+func TestParallelWriterWriteResetsOffsetOnChunkBoundary(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chunkCh := make(chan chunk, 1)
+	pw := &parallelWriter{
+		ctx:     ctx,
+		cancel:  cancel,
+		chunkCh: chunkCh,
+		driver:  &driver{},
+	}
+	pw.buf = make([]byte, 0, 4) // pre-populated so Write doesn't touch driver.pool
+
+	n, err := pw.Write([]byte("abcd"))
+	if err != nil || n != 4 {
+		t.Fatalf("Write() = (%v, %v), want (4, nil)", n, err)
+	}
+	if pw.buf != nil || pw.offset != 0 {
+		t.Fatalf("after an exact chunk-boundary write, buf = %v, offset = %v, want (nil, 0)", pw.buf, pw.offset)
+	}
+
+	select {
+	case <-chunkCh:
+	default:
+		t.Fatalf("expected the full chunk to have been queued")
+	}
+}
+
+// TestParallelWriterCloseWaitsForFinalChunkAck guards against the Close()/
+// doneCh race: Close must not close doneCh until a worker has actually
+// finished the final chunk, or a worker parked in select could pick the
+// now-ready doneCh case over the still-buffered chunk and strand it.
+func TestParallelWriterCloseWaitsForFinalChunkAck(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wg := &sync.WaitGroup{}
+	chunkCh := make(chan chunk, 1)
+	doneCh := make(chan struct{})
+	pw := &parallelWriter{
+		ctx:     ctx,
+		cancel:  cancel,
+		wg:      wg,
+		chunkCh: chunkCh,
+		doneCh:  doneCh,
+		driver:  &driver{},
+	}
+	pw.buf = make([]byte, 2, 4)
+	pw.offset = 2
+
+	processed := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case c := <-chunkCh:
+				// Simulate slow processing so a racy Close would have a
+				// window to close doneCh before this chunk is handled.
+				time.Sleep(10 * time.Millisecond)
+				close(processed)
+				if c.done != nil {
+					close(c.done)
+				}
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+
+	if err := pw.Close(); err != nil {
+		t.Fatalf("Close() = %v, want nil", err)
+	}
+
+	select {
+	case <-processed:
+	default:
+		t.Fatalf("Close() returned before the final chunk was processed by a worker")
+	}
+}
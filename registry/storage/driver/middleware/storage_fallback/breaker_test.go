@@ -0,0 +1,114 @@
+package storage_fallback
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTripsOnErrorRate(t *testing.T) {
+	b := newCircuitBreaker()
+	b.minRequests = 4
+	b.errorRate = 0.5
+
+	b.record(nil, 0)
+	b.record(nil, 0)
+	if state, _ := b.status(); state != breakerClosed {
+		t.Fatalf("state = %v, want closed", state)
+	}
+
+	b.record(fmt.Errorf("boom"), 0)
+	b.record(fmt.Errorf("boom"), 0)
+	if state, _ := b.status(); state != breakerOpen {
+		t.Fatalf("state = %v, want open after crossing error rate", state)
+	}
+}
+
+func TestCircuitBreakerTripsOnLatency(t *testing.T) {
+	b := newCircuitBreaker()
+	b.latencyThreshold = 10 * time.Millisecond
+	b.minRequests = 1
+	b.errorRate = 0.5
+
+	b.record(nil, 50*time.Millisecond)
+	if state, _ := b.status(); state != breakerOpen {
+		t.Fatalf("state = %v, want open after a single slow call", state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenSingleProbe(t *testing.T) {
+	b := newCircuitBreaker()
+	b.cooldown = 0
+	b.trip()
+
+	if !b.allow() {
+		t.Fatalf("first allow() after cooldown elapsed should admit the probe")
+	}
+	if state, _ := b.status(); state != breakerHalfOpen {
+		t.Fatalf("state = %v, want half-open", state)
+	}
+	if b.allow() {
+		t.Fatalf("second concurrent allow() during an in-flight probe must be refused")
+	}
+}
+
+func TestCircuitBreakerHalfOpenSuccessCloses(t *testing.T) {
+	b := newCircuitBreaker()
+	b.cooldown = 0
+	b.trip()
+
+	if !b.allow() {
+		t.Fatalf("expected probe to be admitted")
+	}
+	b.record(nil, 0)
+
+	if state, _ := b.status(); state != breakerClosed {
+		t.Fatalf("state = %v, want closed after a successful probe", state)
+	}
+	if !b.allow() {
+		t.Fatalf("breaker should admit calls once closed")
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailureReopens(t *testing.T) {
+	b := newCircuitBreaker()
+	b.cooldown = 0
+	b.trip()
+
+	if !b.allow() {
+		t.Fatalf("expected probe to be admitted")
+	}
+	// Give the re-opened breaker a real cooldown so the next allow() doesn't
+	// immediately transition back to half-open and mask the assertion below.
+	b.cooldown = time.Minute
+	b.record(fmt.Errorf("still broken"), 0)
+
+	if state, _ := b.status(); state != breakerOpen {
+		t.Fatalf("state = %v, want open again after a failed probe", state)
+	}
+	if b.allow() {
+		t.Fatalf("breaker should refuse calls immediately after re-opening")
+	}
+}
+
+func TestTierHealthReportsBreakerState(t *testing.T) {
+	open := newCircuitBreaker()
+	open.trip()
+	sd := &fallbackStorageDriver{
+		tiers: []*tier{
+			{name: "primary", breaker: newCircuitBreaker()},
+			{name: "fallback", breaker: open},
+		},
+	}
+
+	statuses := sd.TierHealth()
+	if len(statuses) != 2 {
+		t.Fatalf("TierHealth() returned %d statuses, want 2", len(statuses))
+	}
+	if statuses[0].Name != "primary" || statuses[0].State != breakerClosed.String() {
+		t.Fatalf("statuses[0] = %+v, want primary/closed", statuses[0])
+	}
+	if statuses[1].Name != "fallback" || statuses[1].State != breakerOpen.String() {
+		t.Fatalf("statuses[1] = %+v, want fallback/open", statuses[1])
+	}
+}
@@ -0,0 +1,269 @@
+package storage_fallback
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	storagedriver "github.com/docker/distribution/registry/storage/driver"
+)
+
+// fakeDriver is a minimal in-memory storagedriver.StorageDriver used to
+// exercise tryTiers, GetContent, Reader, Stat and maybePromote against real
+// tier behavior, rather than just the pure helper functions covered by
+// breaker_test.go/promote_test.go/verify_test.go.
+type fakeDriver struct {
+	name string
+
+	mu      sync.Mutex
+	content map[string][]byte
+	getErr  map[string]error
+	gets    int
+}
+
+func newFakeDriver(name string) *fakeDriver {
+	return &fakeDriver{name: name, content: map[string][]byte{}, getErr: map[string]error{}}
+}
+
+func (d *fakeDriver) Name() string { return d.name }
+
+func (d *fakeDriver) GetContent(ctx context.Context, path string) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.gets++
+	if err, ok := d.getErr[path]; ok {
+		return nil, err
+	}
+	c, ok := d.content[path]
+	if !ok {
+		return nil, storagedriver.PathNotFoundError{Path: path, DriverName: d.name}
+	}
+	return c, nil
+}
+
+func (d *fakeDriver) PutContent(ctx context.Context, path string, content []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cp := make([]byte, len(content))
+	copy(cp, content)
+	d.content[path] = cp
+	return nil
+}
+
+func (d *fakeDriver) Reader(ctx context.Context, path string, offset int64) (io.ReadCloser, error) {
+	d.mu.Lock()
+	c, ok := d.content[path]
+	d.mu.Unlock()
+	if !ok {
+		return nil, storagedriver.PathNotFoundError{Path: path, DriverName: d.name}
+	}
+	return ioutil.NopCloser(bytes.NewReader(c[offset:])), nil
+}
+
+func (d *fakeDriver) Writer(ctx context.Context, path string, append bool) (storagedriver.FileWriter, error) {
+	return nil, storagedriver.ErrUnsupportedMethod{DriverName: d.name}
+}
+
+func (d *fakeDriver) Stat(ctx context.Context, path string) (storagedriver.FileInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c, ok := d.content[path]
+	if !ok {
+		return nil, storagedriver.PathNotFoundError{Path: path, DriverName: d.name}
+	}
+	return fakeFileInfo{path: path, size: int64(len(c))}, nil
+}
+
+func (d *fakeDriver) List(ctx context.Context, path string) ([]string, error) { return nil, nil }
+
+func (d *fakeDriver) Move(ctx context.Context, sourcePath string, destPath string) error { return nil }
+
+func (d *fakeDriver) Delete(ctx context.Context, path string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.content, path)
+	return nil
+}
+
+func (d *fakeDriver) URLFor(ctx context.Context, path string, options map[string]interface{}) (string, error) {
+	return "", storagedriver.ErrUnsupportedMethod{DriverName: d.name}
+}
+
+func (d *fakeDriver) Walk(ctx context.Context, path string, f storagedriver.WalkFn) error { return nil }
+
+func (d *fakeDriver) hasContent(path string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c, ok := d.content[path]
+	return c, ok
+}
+
+type fakeFileInfo struct {
+	path string
+	size int64
+}
+
+func (f fakeFileInfo) Path() string       { return f.path }
+func (f fakeFileInfo) Size() int64        { return f.size }
+func (f fakeFileInfo) ModTime() time.Time { return time.Time{} }
+func (f fakeFileInfo) IsDir() bool        { return false }
+
+// blobPath builds a content-addressable blob path whose digest matches
+// content, the layout verifyBlob/digestForPath expect.
+func blobPath(content []byte) string {
+	h := sha256.Sum256(content)
+	digest := hex.EncodeToString(h[:])
+	return "/docker/registry/v2/blobs/sha256/" + digest[:2] + "/" + digest + "/data"
+}
+
+func newTestFallbackDriver(primary, fallback *fakeDriver) *fallbackStorageDriver {
+	return &fallbackStorageDriver{
+		StorageDriver:  primary,
+		tiers:          []*tier{buildTier(primary.name, primary, nil), buildTier(fallback.name, fallback, nil)},
+		maxPromoteSize: defaultMaxPromoteSize,
+	}
+}
+
+func TestTryTiersSkipsOpenBreakerTierThenFallsBack(t *testing.T) {
+	primary := newFakeDriver("primary")
+	fallback := newFakeDriver("fallback")
+	path := blobPath([]byte("hello"))
+	fallback.content[path] = []byte("hello")
+
+	sd := newTestFallbackDriver(primary, fallback)
+	sd.tiers[0].breaker.trip()
+	sd.tiers[0].breaker.cooldown = time.Hour // stay open for the duration of this test
+
+	content, err := sd.GetContent(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetContent() = %v, want nil", err)
+	}
+	if string(content) != "hello" {
+		t.Fatalf("GetContent() = %q, want %q", content, "hello")
+	}
+	if primary.gets != 0 {
+		t.Fatalf("primary.GetContent called %d times, want 0: an open breaker must skip the tier entirely", primary.gets)
+	}
+}
+
+func TestGetContentPromotesOnFallbackHit(t *testing.T) {
+	primary := newFakeDriver("primary")
+	fallback := newFakeDriver("fallback")
+	path := blobPath([]byte("hello"))
+	fallback.content[path] = []byte("hello")
+
+	sd := newTestFallbackDriver(primary, fallback)
+	sd.writeBack = true
+	sd.promoteCh = make(chan string, 4)
+	sd.runPromotionWorkers(1)
+
+	content, err := sd.GetContent(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetContent() = %v, want nil", err)
+	}
+	if string(content) != "hello" {
+		t.Fatalf("GetContent() = %q, want %q", content, "hello")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if c, ok := primary.hasContent(path); ok && string(c) == "hello" {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("primary was never promoted to after a write_back fallback hit")
+}
+
+func TestMaybePromoteDedupesConcurrentPromotions(t *testing.T) {
+	primary := newFakeDriver("primary")
+	fallback := newFakeDriver("fallback")
+	sd := newTestFallbackDriver(primary, fallback)
+	sd.writeBack = true
+	sd.promoteCh = make(chan string, 4) // no worker draining it during this test
+
+	path := "/docker/registry/v2/blobs/sha256/ab/abcdef/data"
+	sd.maybePromote(context.Background(), path, false)
+	sd.maybePromote(context.Background(), path, false)
+
+	if got := len(sd.promoteCh); got != 1 {
+		t.Fatalf("promoteCh has %d queued paths, want exactly 1: a second concurrent promotion for the same path must be deduped", got)
+	}
+	if _, loaded := sd.inflight.Load(path); !loaded {
+		t.Fatalf("inflight does not track %v after maybePromote", path)
+	}
+}
+
+func TestGetContentRepairsAndRestoresCorruptedPrimary(t *testing.T) {
+	good := []byte("hello")
+	path := blobPath(good)
+
+	primary := newFakeDriver("primary")
+	primary.content[path] = []byte("corrupted")
+	fallback := newFakeDriver("fallback")
+	fallback.content[path] = good
+
+	sd := newTestFallbackDriver(primary, fallback)
+	sd.verifyAlgorithm = "sha256"
+	sd.repair = true
+	sd.promoteCh = make(chan string, 4)
+	sd.runPromotionWorkers(1)
+
+	content, err := sd.GetContent(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetContent() = %v, want nil (should fall back to a healthy tier)", err)
+	}
+	if string(content) != "hello" {
+		t.Fatalf("GetContent() = %q, want %q", content, good)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if c, ok := primary.hasContent(path); ok && string(c) == "hello" {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("corrupted primary was never repaired/restored from a healthy tier")
+}
+
+func TestReaderRepairsAndRestoresCorruptedPrimary(t *testing.T) {
+	good := []byte("hello")
+	path := blobPath(good)
+
+	primary := newFakeDriver("primary")
+	primary.content[path] = []byte("corrupted")
+	fallback := newFakeDriver("fallback")
+	fallback.content[path] = good
+
+	sd := newTestFallbackDriver(primary, fallback)
+	sd.verifyAlgorithm = "sha256"
+	sd.repair = true
+	sd.promoteCh = make(chan string, 4)
+	sd.runPromotionWorkers(1)
+
+	r, err := sd.Reader(context.Background(), path, 0)
+	if err != nil {
+		t.Fatalf("Reader() = %v, want nil", err)
+	}
+	// Verification only completes once the caller has read through EOF.
+	if _, err := ioutil.ReadAll(r); err != nil {
+		t.Fatalf("reading primary content failed: %v", err)
+	}
+	r.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if c, ok := primary.hasContent(path); ok && string(c) == "hello" {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("corrupted primary was never repaired/restored after a Reader verify failure")
+}
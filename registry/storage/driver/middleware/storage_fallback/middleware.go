@@ -2,75 +2,831 @@ package storage_fallback
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	dcontext "github.com/docker/distribution/context"
+	"github.com/docker/distribution/health"
 	storagedriver "github.com/docker/distribution/registry/storage/driver"
 	"github.com/docker/distribution/registry/storage/driver/factory"
 	storagemiddleware "github.com/docker/distribution/registry/storage/driver/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"hash"
 	"io"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+const (
+	// defaultPromotionWorkers is the number of background goroutines used to
+	// copy objects from a lower tier into the primary when write-back is
+	// enabled.
+	defaultPromotionWorkers = 4
+
+	// defaultMaxPromoteSize bounds how large an object can be before it is
+	// skipped for promotion, so a handful of large blobs can't monopolize
+	// the worker pool or blow up memory on the primary.
+	defaultMaxPromoteSize = 512 << 20 // 512MB
+
+	// defaultPromoteTimeout bounds a single promote() call, so a wedged or
+	// slow tier can't hang a background worker indefinitely -- the worker
+	// pool is small and shared, and a handful of stuck paths against a bad
+	// tier would otherwise exhaust it.
+	defaultPromoteTimeout = 30 * time.Second
+
+	// defaultTierStateRefreshInterval is how often tierStateGauge is
+	// refreshed from TierHealth in the background, so /metrics reflects
+	// live breaker state instead of staying stuck at its zero value.
+	defaultTierStateRefreshInterval = 15 * time.Second
+
+	defaultBreakerWindow      = 1 * time.Minute
+	defaultBreakerErrorRate   = 0.5
+	defaultBreakerMinRequests = 10
+	defaultBreakerCooldown    = 30 * time.Second
+
+	// defaultBreakerLatencyThreshold trips the breaker on sustained slow
+	// calls even when they return no error, since a tier wedged on
+	// multi-second latency is as unusable as one returning errors.
+	defaultBreakerLatencyThreshold = 2 * time.Second
+)
+
+var (
+	tierStateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "registry",
+		Subsystem: "storage_fallback",
+		Name:      "tier_state",
+		Help:      "Current circuit breaker state per tier (0=closed, 1=half-open, 2=open).",
+	}, []string{"tier"})
+
+	tierRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "registry",
+		Subsystem: "storage_fallback",
+		Name:      "tier_requests_total",
+		Help:      "Requests attempted against each tier, by outcome.",
+	}, []string{"tier", "outcome"})
+
+	promotionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "registry",
+		Subsystem: "storage_fallback",
+		Name:      "promotions_total",
+		Help:      "Write-back promotions into the primary, by outcome.",
+	}, []string{"outcome"})
+
+	verifyFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "registry",
+		Subsystem: "storage_fallback",
+		Name:      "verify_failures_total",
+		Help:      "Blobs served by the primary whose content did not match their path-encoded digest.",
+	})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(tierStateGauge, tierRequestsTotal, promotionsTotal, verifyFailuresTotal)
+	})
+}
+
+// blobDigestPattern matches the content-addressable blob layout used by the
+// registry's storage backend: /docker/registry/v2/blobs/<algorithm>/<first
+// two hex chars>/<hex digest>/data.
+var blobDigestPattern = regexp.MustCompile(`^/docker/registry/v2/blobs/([a-z0-9]+)/[a-f0-9]{2}/([a-f0-9]+)/data$`)
+
+// digestForPath extracts the algorithm and hex digest encoded in a
+// content-addressable blob path, reporting ok=false for any path that
+// doesn't follow that layout (manifests, tags, uploads, etc.).
+func digestForPath(p string) (algorithm, digestHex string, ok bool) {
+	m := blobDigestPattern.FindStringSubmatch(p)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+func newDigestHash(algorithm string) (hash.Hash, bool) {
+	switch algorithm {
+	case "sha256":
+		return sha256.New(), true
+	case "sha512":
+		return sha512.New(), true
+	default:
+		return nil, false
+	}
+}
+
+// Metrics tracks fallback hit/miss/promotion counters for a
+// fallbackStorageDriver. All fields are updated with atomic operations and
+// may be read concurrently via Snapshot.
+type Metrics struct {
+	Hits            uint64
+	Misses          uint64
+	Promotions      uint64
+	PromotionErrors uint64
+	VerifyFailures  uint64
+}
+
+// Snapshot returns a copy of m safe for inspection.
+func (m *Metrics) Snapshot() Metrics {
+	return Metrics{
+		Hits:            atomic.LoadUint64(&m.Hits),
+		Misses:          atomic.LoadUint64(&m.Misses),
+		Promotions:      atomic.LoadUint64(&m.Promotions),
+		PromotionErrors: atomic.LoadUint64(&m.PromotionErrors),
+		VerifyFailures:  atomic.LoadUint64(&m.VerifyFailures),
+	}
+}
+
+// breakerState is the lifecycle of a per-tier circuit breaker.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerHalfOpen
+	breakerOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker tracks a rolling error rate for a single tier, counting a
+// call that takes longer than latencyThreshold as a failure. Once the
+// error rate crosses errorRate (given at least minRequests samples in the
+// current window), the breaker trips open, skipping the tier entirely for
+// cooldown before allowing a single half-open probe through.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	window           time.Duration
+	errorRate        float64
+	minRequests      int
+	cooldown         time.Duration
+	latencyThreshold time.Duration // 0 disables latency-based tripping
+
+	state         breakerState
+	openedAt      time.Time
+	windowFrom    time.Time
+	successes     int
+	failures      int
+	lastErr       error
+	halfOpenProbe bool // true while a half-open probe is in flight
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{
+		window:           defaultBreakerWindow,
+		errorRate:        defaultBreakerErrorRate,
+		minRequests:      defaultBreakerMinRequests,
+		cooldown:         defaultBreakerCooldown,
+		latencyThreshold: defaultBreakerLatencyThreshold,
+		windowFrom:       time.Now(),
+	}
+}
+
+// allow reports whether a call should be attempted against this tier right
+// now, transitioning an open breaker into half-open once its cooldown has
+// elapsed so a single probe request can test recovery. Only one caller is
+// ever let through while half-open; every other concurrent caller is
+// refused until that probe's outcome closes or re-opens the breaker.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.state = breakerHalfOpen
+		b.halfOpenProbe = false
+	}
+	if b.state == breakerHalfOpen {
+		if b.halfOpenProbe {
+			return false
+		}
+		b.halfOpenProbe = true
+		return true
+	}
+	return b.state != breakerOpen
+}
+
+// record reports the outcome of a single call: err (nil on success) and how
+// long the call took. A call that returns no error but runs slower than
+// latencyThreshold is treated as a failure for trip purposes, since a tier
+// that never errors but never answers in time is just as unusable.
+func (b *circuitBreaker) record(err error, d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	slow := b.latencyThreshold > 0 && d > b.latencyThreshold
+	if err == nil && !slow {
+		if b.state == breakerHalfOpen {
+			b.reset()
+			return
+		}
+		b.rollWindow()
+		b.successes++
+		return
+	}
+
+	if err != nil {
+		b.lastErr = err
+	} else {
+		b.lastErr = fmt.Errorf("call took %v, exceeding latency threshold %v", d, b.latencyThreshold)
+	}
+
+	if b.state == breakerHalfOpen {
+		b.trip()
+		return
+	}
+
+	b.rollWindow()
+	b.failures++
+
+	total := b.successes + b.failures
+	if total >= b.minRequests && float64(b.failures)/float64(total) >= b.errorRate {
+		b.trip()
+	}
+}
+
+func (b *circuitBreaker) trip() {
+	b.state = breakerOpen
+	b.openedAt = time.Now()
+	b.successes, b.failures = 0, 0
+	b.windowFrom = time.Now()
+	b.halfOpenProbe = false
+}
+
+func (b *circuitBreaker) reset() {
+	b.state = breakerClosed
+	b.successes, b.failures = 0, 0
+	b.windowFrom = time.Now()
+	b.halfOpenProbe = false
+}
+
+func (b *circuitBreaker) rollWindow() {
+	if time.Since(b.windowFrom) > b.window {
+		b.successes, b.failures = 0, 0
+		b.windowFrom = time.Now()
+	}
+}
+
+func (b *circuitBreaker) status() (breakerState, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.lastErr
+}
+
+// tier is one entry in the fallback chain: tiers[0] is always the primary.
+type tier struct {
+	name    string
+	driver  storagedriver.StorageDriver
+	breaker *circuitBreaker
+}
+
+// TierHealth summarizes the live state of a single tier for /debug/health
+// and operator tooling.
+type TierHealth struct {
+	Name  string
+	State string
+	Err   error
+}
+
 type fallbackStorageDriver struct {
-	storagedriver.StorageDriver
-	Fallback storagedriver.StorageDriver
+	storagedriver.StorageDriver // primary; methods not overridden below go straight here
+
+	tiers []*tier // tiers[0].driver == StorageDriver, tiers[1:] are fallbacks in priority order
+
+	writeBack      bool
+	maxPromoteSize int64
+	promoteAllow   []string
+	promoteDeny    []string
+
+	promoteCh chan string
+	inflight  sync.Map // path -> struct{}, dedupes concurrent promotions
+
+	verifyAlgorithm string // e.g. "sha256"; empty disables verification
+	repair          bool
+
+	Metrics Metrics
+}
+
+// verifyBlob reports an error if p is a content-addressable blob path whose
+// algorithm matches the configured verify option and content doesn't hash
+// to the digest encoded in p. Any other path, or an unconfigured/unknown
+// algorithm, is treated as trivially valid.
+func (sd *fallbackStorageDriver) verifyBlob(p string, content []byte) error {
+	if sd.verifyAlgorithm == "" {
+		return nil
+	}
+	algorithm, wantHex, ok := digestForPath(p)
+	if !ok || algorithm != sd.verifyAlgorithm {
+		return nil
+	}
+
+	h, ok := newDigestHash(algorithm)
+	if !ok {
+		return nil
+	}
+	h.Write(content)
+	if gotHex := hex.EncodeToString(h.Sum(nil)); gotHex != wantHex {
+		return &verifyMismatchError{fmt.Errorf("storage_fallback: content at %v does not match digest %v:%v (got %v:%v)", p, algorithm, wantHex, algorithm, gotHex)}
+	}
+	return nil
+}
+
+// verifyMismatchError marks a digest verification failure on a single blob.
+// It's deliberately distinct from a transport/backend error: one corrupted
+// object says nothing about whether the tier that served it is healthy, so
+// tryTiers excludes it from circuit-breaker accounting instead of treating
+// it like any other tier failure.
+type verifyMismatchError struct {
+	err error
+}
+
+func (e *verifyMismatchError) Error() string { return e.err.Error() }
+func (e *verifyMismatchError) Unwrap() error { return e.err }
+
+// maybeRepair deletes a corrupted primary object, identified by a verify
+// failure, so that it stops being served until write-back (or a later
+// repair-by-replace) restores a good copy from a healthy tier.
+func (sd *fallbackStorageDriver) maybeRepair(ctx context.Context, p string) {
+	atomic.AddUint64(&sd.Metrics.VerifyFailures, 1)
+	verifyFailuresTotal.Inc()
+
+	if !sd.repair {
+		return
+	}
+	if err := sd.StorageDriver.Delete(ctx, p); err != nil {
+		dcontext.GetLogger(ctx).WithError(err).Warnf("storage_fallback: repair(%v): deleting corrupted primary object failed", p)
+	}
 }
 
 func (sd *fallbackStorageDriver) Name() string {
-	return fmt.Sprintf("%v -> %v", sd.StorageDriver.Name(), sd.Fallback.Name())
+	names := make([]string, len(sd.tiers))
+	for i, t := range sd.tiers {
+		names[i] = t.driver.Name()
+	}
+	return strings.Join(names, " -> ")
+}
+
+// TierHealth reports the current circuit breaker state of every configured
+// tier, primary first, and refreshes tierStateGauge as a side effect.
+// health.RegisterFunc surfaces a per-tier open/closed check via
+// /debug/health; runTierStateGaugeUpdater calls this periodically so
+// tier_state in /metrics reflects live breaker state too.
+func (sd *fallbackStorageDriver) TierHealth() []TierHealth {
+	statuses := make([]TierHealth, len(sd.tiers))
+	for i, t := range sd.tiers {
+		state, err := t.breaker.status()
+		statuses[i] = TierHealth{Name: t.name, State: state.String(), Err: err}
+		tierStateGauge.WithLabelValues(t.name).Set(float64(state))
+	}
+	return statuses
+}
+
+// tryTiers attempts fn against each tier in priority order, skipping any
+// tier whose breaker is currently open, and records the outcome against
+// that tier's breaker. It stops at the first tier fn succeeds on.
+func (sd *fallbackStorageDriver) tryTiers(ctx context.Context, op string, fn func(i int, d storagedriver.StorageDriver) error) error {
+	var lastErr error
+	attempted := false
+
+	for i, t := range sd.tiers {
+		if !t.breaker.allow() {
+			tierRequestsTotal.WithLabelValues(t.name, "skipped").Inc()
+			continue
+		}
+		attempted = true
+
+		start := time.Now()
+		err := fn(i, t.driver)
+		elapsed := time.Since(start)
+
+		var verr *verifyMismatchError
+		if !errors.As(err, &verr) {
+			t.breaker.record(err, elapsed)
+		}
+
+		if err != nil {
+			tierRequestsTotal.WithLabelValues(t.name, "error").Inc()
+			lastErr = err
+			if i == 0 {
+				atomic.AddUint64(&sd.Metrics.Misses, 1)
+			}
+			if i < len(sd.tiers)-1 {
+				dcontext.GetLogger(ctx).WithError(err).Warnf("storage_fallback: %v failed on tier %q, trying next tier", op, t.name)
+			}
+			continue
+		}
+
+		tierRequestsTotal.WithLabelValues(t.name, "success").Inc()
+		if i > 0 {
+			atomic.AddUint64(&sd.Metrics.Hits, 1)
+		}
+		return nil
+	}
+
+	if !attempted {
+		return fmt.Errorf("storage_fallback: %v: all tiers circuit-open", op)
+	}
+	return lastErr
 }
 
 func (sd *fallbackStorageDriver) GetContent(ctx context.Context, path string) ([]byte, error) {
-	result, err := sd.StorageDriver.GetContent(ctx, path)
-	if err != nil {
-		dcontext.GetLogger(ctx).WithError(err).Warnf("GetContent(%v): falling back to %v", path, sd.Fallback.Name())
-		return sd.Fallback.GetContent(ctx, path)
+	var result []byte
+	servedBy := -1
+	primaryVerifyFailed := false
+
+	err := sd.tryTiers(ctx, fmt.Sprintf("GetContent(%v)", path), func(i int, d storagedriver.StorageDriver) error {
+		r, err := d.GetContent(ctx, path)
+		if err != nil {
+			return err
+		}
+		if verr := sd.verifyBlob(path, r); verr != nil {
+			if i == 0 {
+				dcontext.GetLogger(ctx).WithError(verr).Warnf("storage_fallback: GetContent(%v): primary content failed verification", path)
+				primaryVerifyFailed = true
+				sd.maybeRepair(ctx, path)
+			}
+			return verr
+		}
+		result, servedBy = r, i
+		return nil
+	})
+	if err == nil && servedBy > 0 {
+		sd.maybePromote(ctx, path, sd.repair && primaryVerifyFailed)
 	}
 	return result, err
 }
 
+// verifyingReadCloser hashes bytes as they're read and, once the
+// underlying reader reaches EOF, compares the digest against the one
+// encoded in the blob's path. Unlike GetContent, a mismatch here can't
+// stop bytes already delivered to the caller; it only triggers repair so
+// later requests are served from a healthy tier.
+type verifyingReadCloser struct {
+	io.ReadCloser
+	hash      hash.Hash
+	algorithm string
+	wantHex   string
+	sd        *fallbackStorageDriver
+	ctx       context.Context
+	path      string
+	checked   bool
+}
+
+func (r *verifyingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.hash.Write(p[:n])
+	}
+	if err == io.EOF && !r.checked {
+		r.checked = true
+		if gotHex := hex.EncodeToString(r.hash.Sum(nil)); gotHex != r.wantHex {
+			dcontext.GetLogger(r.ctx).Warnf("storage_fallback: Reader(%v): primary content failed verification (got %v:%v, want %v:%v)", r.path, r.algorithm, gotHex, r.algorithm, r.wantHex)
+			r.sd.maybeRepair(r.ctx, r.path)
+			// Unlike GetContent, tryTiers has already returned by the time
+			// this runs (verification only completes at EOF, after bytes
+			// are delivered to the caller), so servedBy > 0 never fires for
+			// a corrupt primary read. Force the same restore-from-fallback
+			// path here, or repair:true only ever deletes for Reader and
+			// never replaces.
+			r.sd.maybePromote(r.ctx, r.path, r.sd.repair)
+		}
+	}
+	return n, err
+}
+
 func (sd *fallbackStorageDriver) Reader(ctx context.Context, path string, offset int64) (io.ReadCloser, error) {
-	result, err := sd.StorageDriver.Reader(ctx, path, offset)
-	if err != nil {
-		dcontext.GetLogger(ctx).WithError(err).Warnf("Reader(%v, %v): falling back to %v", path, offset, sd.Fallback.Name())
-		return sd.Fallback.Reader(ctx, path, offset)
+	var result io.ReadCloser
+	servedBy := -1
+
+	err := sd.tryTiers(ctx, fmt.Sprintf("Reader(%v, %v)", path, offset), func(i int, d storagedriver.StorageDriver) error {
+		r, err := d.Reader(ctx, path, offset)
+		if err != nil {
+			return err
+		}
+		// Verification needs the full object, so only wrap a from-scratch
+		// read of the primary; ranged reads and fallback tiers pass through.
+		if i == 0 && offset == 0 && sd.verifyAlgorithm != "" {
+			if algorithm, wantHex, ok := digestForPath(path); ok && algorithm == sd.verifyAlgorithm {
+				if h, ok := newDigestHash(algorithm); ok {
+					r = &verifyingReadCloser{ReadCloser: r, hash: h, algorithm: algorithm, wantHex: wantHex, sd: sd, ctx: ctx, path: path}
+				}
+			}
+		}
+		result, servedBy = r, i
+		return nil
+	})
+	if err == nil && servedBy > 0 {
+		sd.maybePromote(ctx, path, false)
 	}
 	return result, err
 }
 
 func (sd *fallbackStorageDriver) Stat(ctx context.Context, path string) (storagedriver.FileInfo, error) {
-	result, err := sd.StorageDriver.Stat(ctx, path)
-	if err != nil {
-		dcontext.GetLogger(ctx).WithError(err).Warnf("Stat(%v): falling back to %v", path, sd.Fallback.Name())
-		return sd.Fallback.Stat(ctx, path)
+	var result storagedriver.FileInfo
+	servedBy := -1
+
+	err := sd.tryTiers(ctx, fmt.Sprintf("Stat(%v)", path), func(i int, d storagedriver.StorageDriver) error {
+		r, err := d.Stat(ctx, path)
+		if err != nil {
+			return err
+		}
+		result, servedBy = r, i
+		return nil
+	})
+	if err == nil && servedBy > 0 {
+		sd.maybePromote(ctx, path, false)
 	}
 	return result, err
 }
 
 func (sd *fallbackStorageDriver) URLFor(ctx context.Context, path string, options map[string]interface{}) (string, error) {
-	if _, err := sd.StorageDriver.Stat(ctx, path); err != nil {
-		dcontext.GetLogger(ctx).WithError(err).Warnf("URLFor(%v): Stat() failed, falling back to %v", path, sd.Fallback.Name())
-		return sd.Fallback.URLFor(ctx, path, options)
+	var result string
+
+	err := sd.tryTiers(ctx, fmt.Sprintf("URLFor(%v)", path), func(i int, d storagedriver.StorageDriver) error {
+		if _, err := d.Stat(ctx, path); err != nil {
+			return err
+		}
+		r, err := d.URLFor(ctx, path, options)
+		if err != nil {
+			return err
+		}
+		result = r
+		return nil
+	})
+	return result, err
+}
+
+// maybePromote schedules path to be copied from the serving tier into the
+// primary if write-back is enabled, the path survives the allow/deny globs,
+// and no promotion for it is already in flight. force bypasses the
+// write-back gate, used to route a repair (restoring a primary copy that
+// failed verification) through the same bounded, async worker pool rather
+// than a synchronous write. It never blocks the caller.
+func (sd *fallbackStorageDriver) maybePromote(ctx context.Context, p string, force bool) {
+	if !sd.writeBack && !force {
+		return
+	}
+	if !sd.promotable(p) {
+		return
+	}
+	if _, loaded := sd.inflight.LoadOrStore(p, struct{}{}); loaded {
+		return
+	}
+
+	select {
+	case sd.promoteCh <- p:
+	default:
+		// worker pool is saturated; drop the promotion rather than block
+		// the request that triggered it.
+		sd.inflight.Delete(p)
+		dcontext.GetLogger(ctx).Warnf("storage_fallback: promotion queue full, dropping promote(%v)", p)
 	}
+}
 
-	return sd.StorageDriver.URLFor(ctx, path, options)
+// promotable reports whether p is eligible for write-back promotion
+// according to the configured allow/deny globs. Deny takes precedence over
+// allow; an empty allow list matches everything.
+func (sd *fallbackStorageDriver) promotable(p string) bool {
+	for _, pattern := range sd.promoteDeny {
+		if ok, _ := path.Match(pattern, p); ok {
+			return false
+		}
+	}
+	if len(sd.promoteAllow) == 0 {
+		return true
+	}
+	for _, pattern := range sd.promoteAllow {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
 }
 
-func newFallbackStorageDriver(sd storagedriver.StorageDriver, options map[string]interface{}) (storagedriver.StorageDriver, error) {
-	driverName, ok := options["driver"].(string)
+// runPromotionWorkers starts n goroutines that drain promoteCh, copying
+// each path from the first tier that still has it into the primary.
+func (sd *fallbackStorageDriver) runPromotionWorkers(n int) {
+	for i := 0; i < n; i++ {
+		go func() {
+			for p := range sd.promoteCh {
+				sd.promote(p)
+				sd.inflight.Delete(p)
+			}
+		}()
+	}
+}
+
+func (sd *fallbackStorageDriver) promote(p string) {
+	ctx, cancel := context.WithTimeout(dcontext.Background(), defaultPromoteTimeout)
+	defer cancel()
+
+	for _, t := range sd.tiers[1:] {
+		if !t.breaker.allow() {
+			continue
+		}
+
+		start := time.Now()
+		info, err := t.driver.Stat(ctx, p)
+		t.breaker.record(err, time.Since(start))
+		if err != nil {
+			continue
+		}
+		if info.IsDir() || info.Size() > sd.maxPromoteSize {
+			return
+		}
+
+		start = time.Now()
+		reader, err := t.driver.Reader(ctx, p, 0)
+		t.breaker.record(err, time.Since(start))
+		if err != nil {
+			continue
+		}
+
+		content, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			dcontext.GetLogger(ctx).WithError(err).Warnf("storage_fallback: promote(%v): buffering content from tier %q failed", p, t.name)
+			atomic.AddUint64(&sd.Metrics.PromotionErrors, 1)
+			promotionsTotal.WithLabelValues("error").Inc()
+			return
+		}
+
+		if err := sd.StorageDriver.PutContent(ctx, p, content); err != nil {
+			dcontext.GetLogger(ctx).WithError(err).Warnf("storage_fallback: promote(%v): writing to primary failed", p)
+			atomic.AddUint64(&sd.Metrics.PromotionErrors, 1)
+			promotionsTotal.WithLabelValues("error").Inc()
+			return
+		}
+
+		atomic.AddUint64(&sd.Metrics.Promotions, 1)
+		promotionsTotal.WithLabelValues("success").Inc()
+		return
+	}
+}
+
+func stringSlice(v interface{}) []string {
+	switch vv := v.(type) {
+	case []string:
+		return vv
+	case []interface{}:
+		out := make([]string, 0, len(vv))
+		for _, e := range vv {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// buildTier wraps d in a tier named name, configuring its circuit breaker
+// from any breaker_* keys present in opts.
+func buildTier(name string, d storagedriver.StorageDriver, opts map[string]interface{}) *tier {
+	b := newCircuitBreaker()
+	if v, ok := opts["breaker_error_rate"].(float64); ok {
+		b.errorRate = v
+	}
+	if v, ok := opts["breaker_min_requests"].(int); ok {
+		b.minRequests = v
+	}
+	if v, ok := opts["breaker_window_seconds"].(int); ok {
+		b.window = time.Duration(v) * time.Second
+	}
+	if v, ok := opts["breaker_cooldown_seconds"].(int); ok {
+		b.cooldown = time.Duration(v) * time.Second
+	}
+	if v, ok := opts["breaker_latency_threshold_ms"].(int); ok {
+		b.latencyThreshold = time.Duration(v) * time.Millisecond
+	}
+	return &tier{name: name, driver: d, breaker: b}
+}
+
+// createFallbackDriver instantiates a fallback driver from a `driver` (or
+// legacy `driverName`) key plus that driver's own parameters, all read from
+// opts, and returns the name it should be tracked under.
+func createFallbackDriver(opts map[string]interface{}) (string, storagedriver.StorageDriver, error) {
+	driverName, ok := opts["driver"].(string)
 	if !ok {
-		driverName, ok = options["driverName"].(string)  // TODO: fully deprecate driverName
+		driverName, ok = opts["driverName"].(string) // TODO: fully deprecate driverName
 	}
 	if !ok {
-		return nil, fmt.Errorf("failed to extract driver or driverName from options")
+		return "", nil, fmt.Errorf("failed to extract driver or driverName from options")
 	}
 
-	fallback, err := factory.Create(driverName, options)
-
+	d, err := factory.Create(driverName, opts)
 	if err != nil {
-		return nil, err
+		return "", nil, err
+	}
+
+	name, _ := opts["name"].(string)
+	if name == "" {
+		name = driverName
+	}
+	return name, d, nil
+}
+
+func newFallbackStorageDriver(sd storagedriver.StorageDriver, options map[string]interface{}) (storagedriver.StorageDriver, error) {
+	registerMetrics()
+
+	fsd := &fallbackStorageDriver{
+		StorageDriver:  sd,
+		maxPromoteSize: defaultMaxPromoteSize,
+	}
+
+	fsd.tiers = append(fsd.tiers, buildTier("primary", sd, options))
+
+	if rawTiers, ok := options["fallbacks"].([]interface{}); ok {
+		for _, raw := range rawTiers {
+			tierOpts, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("storage_fallback: each entry in fallbacks must be an object")
+			}
+			name, d, err := createFallbackDriver(tierOpts)
+			if err != nil {
+				return nil, err
+			}
+			fsd.tiers = append(fsd.tiers, buildTier(name, d, tierOpts))
+		}
+	} else {
+		// Backward-compatible single-fallback form: driver/driverName given
+		// directly at the top level of options.
+		name, d, err := createFallbackDriver(options)
+		if err != nil {
+			return nil, err
+		}
+		fsd.tiers = append(fsd.tiers, buildTier(name, d, options))
+	}
+
+	if len(fsd.tiers) < 2 {
+		return nil, fmt.Errorf("storage_fallback: at least one fallback tier is required")
+	}
+
+	if writeBack, ok := options["write_back"].(bool); ok {
+		fsd.writeBack = writeBack
+	}
+	if size, ok := options["max_promote_size"].(int); ok {
+		fsd.maxPromoteSize = int64(size)
 	}
+	fsd.promoteAllow = stringSlice(options["promote_allow"])
+	fsd.promoteDeny = stringSlice(options["promote_deny"])
 
-	return &fallbackStorageDriver{StorageDriver: sd, Fallback: fallback}, nil
+	if verify, ok := options["verify"].(string); ok {
+		fsd.verifyAlgorithm = verify
+	}
+	if repair, ok := options["repair"].(bool); ok {
+		fsd.repair = repair
+	}
+
+	if fsd.writeBack || fsd.repair {
+		workers := defaultPromotionWorkers
+		if w, ok := options["promote_workers"].(int); ok && w > 0 {
+			workers = w
+		}
+		fsd.promoteCh = make(chan string, workers)
+		fsd.runPromotionWorkers(workers)
+	}
+
+	for _, t := range fsd.tiers {
+		t := t
+		health.RegisterFunc(fmt.Sprintf("storage_fallback:%v", t.name), func() error {
+			if state, err := t.breaker.status(); state == breakerOpen {
+				return fmt.Errorf("tier %q circuit open: %v", t.name, err)
+			}
+			return nil
+		})
+	}
+
+	go fsd.runTierStateGaugeUpdater(defaultTierStateRefreshInterval)
+
+	return fsd, nil
+}
+
+// runTierStateGaugeUpdater refreshes tierStateGauge on interval for as long
+// as the driver exists; TierHealth has no caller otherwise, so the gauge it
+// updates would stay registered at its zero value forever.
+func (sd *fallbackStorageDriver) runTierStateGaugeUpdater(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		sd.TierHealth()
+	}
 }
 
 func init() {
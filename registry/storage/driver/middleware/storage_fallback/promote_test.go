@@ -0,0 +1,32 @@
+package storage_fallback
+
+import "testing"
+
+func TestPromotableNoGlobsMatchesEverything(t *testing.T) {
+	sd := &fallbackStorageDriver{}
+	if !sd.promotable("/docker/registry/v2/blobs/sha256/ab/abcdef/data") {
+		t.Fatalf("promotable() = false with no allow/deny globs configured, want true")
+	}
+}
+
+func TestPromotableDenyTakesPrecedence(t *testing.T) {
+	sd := &fallbackStorageDriver{
+		promoteAllow: []string{"/docker/registry/v2/blobs/sha256/*/*/data"},
+		promoteDeny:  []string{"/docker/registry/v2/blobs/sha256/*/*/data"},
+	}
+	if sd.promotable("/docker/registry/v2/blobs/sha256/ab/abcdef/data") {
+		t.Fatalf("promotable() = true, want false: deny must win over an overlapping allow")
+	}
+}
+
+func TestPromotableAllowListRestricts(t *testing.T) {
+	sd := &fallbackStorageDriver{
+		promoteAllow: []string{"/docker/registry/v2/blobs/sha256/*/*/data"},
+	}
+	if !sd.promotable("/docker/registry/v2/blobs/sha256/ab/abcdef/data") {
+		t.Fatalf("promotable() = false for a path matching the allow list, want true")
+	}
+	if sd.promotable("/docker/registry/v2/repositories/foo/_layers/link") {
+		t.Fatalf("promotable() = true for a path outside the allow list, want false")
+	}
+}
@@ -0,0 +1,66 @@
+package storage_fallback
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestDigestForPath(t *testing.T) {
+	h := sha256.Sum256([]byte("hello"))
+	digest := hex.EncodeToString(h[:])
+	path := "/docker/registry/v2/blobs/sha256/" + digest[:2] + "/" + digest + "/data"
+
+	algorithm, got, ok := digestForPath(path)
+	if !ok {
+		t.Fatalf("digestForPath(%v) ok = false, want true", path)
+	}
+	if algorithm != "sha256" || got != digest {
+		t.Fatalf("digestForPath(%v) = (%v, %v), want (sha256, %v)", path, algorithm, got, digest)
+	}
+
+	if _, _, ok := digestForPath("/docker/registry/v2/repositories/foo/_manifests/tags/latest/current/link"); ok {
+		t.Fatalf("digestForPath matched a non-blob path")
+	}
+}
+
+func TestVerifyBlobMatches(t *testing.T) {
+	sd := &fallbackStorageDriver{verifyAlgorithm: "sha256"}
+	content := []byte("hello")
+	h := sha256.Sum256(content)
+	digest := hex.EncodeToString(h[:])
+	path := "/docker/registry/v2/blobs/sha256/" + digest[:2] + "/" + digest + "/data"
+
+	if err := sd.verifyBlob(path, content); err != nil {
+		t.Fatalf("verifyBlob() = %v, want nil for matching content", err)
+	}
+}
+
+func TestVerifyBlobMismatch(t *testing.T) {
+	sd := &fallbackStorageDriver{verifyAlgorithm: "sha256"}
+	h := sha256.Sum256([]byte("hello"))
+	digest := hex.EncodeToString(h[:])
+	path := "/docker/registry/v2/blobs/sha256/" + digest[:2] + "/" + digest + "/data"
+
+	err := sd.verifyBlob(path, []byte("goodbye"))
+	if err == nil {
+		t.Fatalf("verifyBlob() = nil, want a mismatch error")
+	}
+	var verr *verifyMismatchError
+	if !errors.As(err, &verr) {
+		t.Fatalf("verifyBlob() returned %T, want *verifyMismatchError", err)
+	}
+}
+
+func TestVerifyBlobDisabledOrUnrelatedPath(t *testing.T) {
+	sd := &fallbackStorageDriver{}
+	if err := sd.verifyBlob("/docker/registry/v2/blobs/sha256/ab/abcdef/data", []byte("anything")); err != nil {
+		t.Fatalf("verifyBlob() = %v, want nil when verification is disabled", err)
+	}
+
+	sd.verifyAlgorithm = "sha256"
+	if err := sd.verifyBlob("/docker/registry/v2/repositories/foo/_layers/link", []byte("anything")); err != nil {
+		t.Fatalf("verifyBlob() = %v, want nil for a non-digest path", err)
+	}
+}
@@ -0,0 +1,313 @@
+package plugin
+
+// Regenerate the proto bindings after editing proto/storagedriver.proto:
+//go:generate protoc --go_out=plugins=grpc:. -I proto proto/storagedriver.proto
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	storagedriver "github.com/docker/distribution/registry/storage/driver"
+	pb "github.com/docker/distribution/registry/storage/driver/plugin/proto"
+	"google.golang.org/grpc"
+)
+
+// dialTimeout bounds how long we wait for a freshly started sidecar to
+// create its unix socket and accept a connection.
+const dialTimeout = 10 * time.Second
+
+// grpcStorageDriver implements storagedriver.StorageDriver by forwarding
+// every call over gRPC to a plugin process started by newGRPCPlugin.
+type grpcStorageDriver struct {
+	cmd    *exec.Cmd
+	conn   *grpc.ClientConn
+	client pb.StorageDriverClient
+}
+
+// newGRPCPlugin starts path as a subprocess with args, pointing it at a
+// freshly created unix socket via the DISTRIBUTION_PLUGIN_SOCKET
+// environment variable, and dials it once the socket appears.
+func newGRPCPlugin(path string, args []string) (storagedriver.StorageDriver, error) {
+	dir, err := ioutil.TempDir("", "distribution-plugin")
+	if err != nil {
+		return nil, fmt.Errorf("plugin: creating socket dir: %v", err)
+	}
+	sockPath := filepath.Join(dir, "plugin.sock")
+
+	cmd := exec.Command(path, args...)
+	cmd.Env = append(os.Environ(), "DISTRIBUTION_PLUGIN_SOCKET="+sockPath)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("plugin: starting %v: %v", path, err)
+	}
+
+	conn, err := waitAndDial(sockPath, dialTimeout)
+	if err != nil {
+		cmd.Process.Kill()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return &grpcStorageDriver{
+		cmd:    cmd,
+		conn:   conn,
+		client: pb.NewStorageDriverClient(conn),
+	}, nil
+}
+
+func waitAndDial(sockPath string, timeout time.Duration) (*grpc.ClientConn, error) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(sockPath); err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			return grpc.DialContext(ctx, "unix://"+sockPath, grpc.WithInsecure(), grpc.WithBlock())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return nil, fmt.Errorf("plugin: sidecar did not create %v within %v", sockPath, timeout)
+}
+
+func (d *grpcStorageDriver) HealthCheck() error {
+	resp, err := d.client.HealthCheck(context.Background(), &pb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if !resp.Healthy {
+		return fmt.Errorf("%v", resp.Error)
+	}
+	return nil
+}
+
+func (d *grpcStorageDriver) Name() string {
+	resp, err := d.client.Name(context.Background(), &pb.NameRequest{})
+	if err != nil {
+		return ""
+	}
+	return resp.Name
+}
+
+func (d *grpcStorageDriver) GetContent(ctx context.Context, path string) ([]byte, error) {
+	resp, err := d.client.GetContent(ctx, &pb.GetContentRequest{Path: path})
+	if err != nil {
+		return nil, err
+	}
+	if resp.NotFound {
+		return nil, storagedriver.PathNotFoundError{Path: path, DriverName: "plugin"}
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%v", resp.Error)
+	}
+	return resp.Content, nil
+}
+
+func (d *grpcStorageDriver) PutContent(ctx context.Context, path string, content []byte) error {
+	resp, err := d.client.PutContent(ctx, &pb.PutContentRequest{Path: path, Content: content})
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%v", resp.Error)
+	}
+	return nil
+}
+
+func (d *grpcStorageDriver) Reader(ctx context.Context, path string, offset int64) (io.ReadCloser, error) {
+	stream, err := d.client.Reader(ctx, &pb.ReaderRequest{Path: path, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	return &grpcReader{stream: stream}, nil
+}
+
+type grpcReader struct {
+	stream pb.StorageDriver_ReaderClient
+	buf    []byte
+}
+
+func (r *grpcReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.stream.Recv()
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, err
+		}
+		if chunk.Error != "" {
+			return 0, fmt.Errorf("%v", chunk.Error)
+		}
+		r.buf = chunk.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *grpcReader) Close() error {
+	return r.stream.CloseSend()
+}
+
+func (d *grpcStorageDriver) Writer(ctx context.Context, path string, doAppend bool) (storagedriver.FileWriter, error) {
+	stream, err := d.client.Write(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcFileWriter{stream: stream, path: path, doAppend: doAppend}, nil
+}
+
+type grpcFileWriter struct {
+	stream    pb.StorageDriver_WriteClient
+	path      string
+	doAppend  bool
+	size      int64
+	closed    bool
+	committed bool
+	cancelled bool
+}
+
+func (w *grpcFileWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fmt.Errorf("Wrote to closed writer")
+	}
+	if err := w.stream.Send(&pb.WriteChunk{Path: w.path, Append: w.doAppend, Data: p}); err != nil {
+		return 0, err
+	}
+	w.size += int64(len(p))
+	return len(p), nil
+}
+
+func (w *grpcFileWriter) Size() int64 {
+	return w.size
+}
+
+func (w *grpcFileWriter) finish(action string) error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if err := w.stream.Send(&pb.WriteChunk{Path: w.path, Action: action}); err != nil {
+		return err
+	}
+	resp, err := w.stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%v", resp.Error)
+	}
+	w.size = resp.Size
+	return nil
+}
+
+func (w *grpcFileWriter) Close() error {
+	return w.finish("close")
+}
+
+func (w *grpcFileWriter) Commit() error {
+	w.committed = true
+	return w.finish("commit")
+}
+
+func (w *grpcFileWriter) Cancel() error {
+	w.cancelled = true
+	return w.finish("cancel")
+}
+
+func (d *grpcStorageDriver) Stat(ctx context.Context, path string) (storagedriver.FileInfo, error) {
+	resp, err := d.client.Stat(ctx, &pb.StatRequest{Path: path})
+	if err != nil {
+		return nil, err
+	}
+	if resp.NotFound {
+		return nil, storagedriver.PathNotFoundError{Path: path, DriverName: "plugin"}
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%v", resp.Error)
+	}
+	return &fileInfo{
+		path:    resp.Path,
+		size:    resp.Size,
+		modTime: time.Unix(resp.ModTimeUnix, 0),
+		isDir:   resp.IsDir,
+	}, nil
+}
+
+type fileInfo struct {
+	path    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+func (f *fileInfo) Path() string       { return f.path }
+func (f *fileInfo) Size() int64        { return f.size }
+func (f *fileInfo) ModTime() time.Time { return f.modTime }
+func (f *fileInfo) IsDir() bool        { return f.isDir }
+
+func (d *grpcStorageDriver) List(ctx context.Context, path string) ([]string, error) {
+	resp, err := d.client.List(ctx, &pb.ListRequest{Path: path})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%v", resp.Error)
+	}
+	return resp.Entries, nil
+}
+
+func (d *grpcStorageDriver) Move(ctx context.Context, sourcePath string, destPath string) error {
+	resp, err := d.client.Move(ctx, &pb.MoveRequest{SourcePath: sourcePath, DestPath: destPath})
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%v", resp.Error)
+	}
+	return nil
+}
+
+func (d *grpcStorageDriver) Delete(ctx context.Context, path string) error {
+	resp, err := d.client.Delete(ctx, &pb.DeleteRequest{Path: path})
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%v", resp.Error)
+	}
+	return nil
+}
+
+// Walk has no corresponding RPC, so it's implemented in terms of List and
+// Stat via storagedriver.WalkFallback, same as any other driver that
+// doesn't natively support a more efficient traversal.
+func (d *grpcStorageDriver) Walk(ctx context.Context, path string, f storagedriver.WalkFn) error {
+	return storagedriver.WalkFallback(ctx, d, path, f)
+}
+
+func (d *grpcStorageDriver) URLFor(ctx context.Context, path string, options map[string]interface{}) (string, error) {
+	strOptions := make(map[string]string, len(options))
+	for k, v := range options {
+		strOptions[k] = fmt.Sprintf("%v", v)
+	}
+
+	resp, err := d.client.URLFor(ctx, &pb.URLForRequest{Path: path, Options: strOptions})
+	if err != nil {
+		return "", err
+	}
+	if resp.Unsupported {
+		return "", storagedriver.ErrUnsupportedMethod{DriverName: "plugin"}
+	}
+	if resp.Error != "" {
+		return "", fmt.Errorf("%v", resp.Error)
+	}
+	return resp.Url, nil
+}
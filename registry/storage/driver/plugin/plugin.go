@@ -0,0 +1,117 @@
+// Package plugin lets third-party storage backends (Dropbox, Google Drive,
+// Backblaze B2, rclone-backed remotes, ...) be used by the registry without
+// being vendored into the registry binary. Following the registration
+// pattern in storage_fallback's init(), a driver is made available under
+// `driver: plugin` and is loaded one of two ways, chosen by the `path`
+// parameter:
+//
+//   - a Go plugin (.so) built with `go build -buildmode=plugin` that
+//     exports a `NewStorageDriver(map[string]interface{}) (storagedriver.StorageDriver, error)`
+//     symbol, loaded in-process via the standard library `plugin` package.
+//   - any other executable, started as a subprocess with `args` and
+//     expected to serve the StorageDriver gRPC service (see proto/) on the
+//     unix socket path passed to it via DISTRIBUTION_PLUGIN_SOCKET.
+//
+// Either way, the resulting driver gets a startup health check so a
+// misconfigured plugin fails loudly at boot rather than on the first
+// request.
+package plugin
+
+import (
+	"fmt"
+	"plugin"
+	"strings"
+
+	storagedriver "github.com/docker/distribution/registry/storage/driver"
+	"github.com/docker/distribution/registry/storage/driver/factory"
+)
+
+// Checker is implemented by plugins that want a deeper startup check than
+// a bare Name() call, e.g. verifying credentials or connectivity to the
+// backend they wrap.
+type Checker interface {
+	HealthCheck() error
+}
+
+type pluginFactory struct{}
+
+func (f *pluginFactory) Create(parameters map[string]interface{}) (storagedriver.StorageDriver, error) {
+	return FromParameters(parameters)
+}
+
+// FromParameters constructs a StorageDriver from the `path` and `args`
+// plugin parameters, performing a startup health check before returning it.
+func FromParameters(parameters map[string]interface{}) (storagedriver.StorageDriver, error) {
+	path, ok := parameters["path"].(string)
+	if !ok || path == "" {
+		return nil, fmt.Errorf("plugin: no path provided")
+	}
+
+	var d storagedriver.StorageDriver
+	var err error
+
+	if strings.HasSuffix(path, ".so") {
+		d, err = loadGoPlugin(path, parameters)
+	} else {
+		d, err = newGRPCPlugin(path, stringSlice(parameters["args"]))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := healthCheck(d); err != nil {
+		return nil, fmt.Errorf("plugin: %v failed startup health check: %v", path, err)
+	}
+
+	return d, nil
+}
+
+func healthCheck(d storagedriver.StorageDriver) error {
+	if d.Name() == "" {
+		return fmt.Errorf("plugin returned an empty driver name")
+	}
+	if c, ok := d.(Checker); ok {
+		return c.HealthCheck()
+	}
+	return nil
+}
+
+func loadGoPlugin(path string, parameters map[string]interface{}) (storagedriver.StorageDriver, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("plugin: opening %v: %v", path, err)
+	}
+
+	sym, err := p.Lookup("NewStorageDriver")
+	if err != nil {
+		return nil, fmt.Errorf("plugin: %v does not export NewStorageDriver: %v", path, err)
+	}
+
+	newDriver, ok := sym.(func(map[string]interface{}) (storagedriver.StorageDriver, error))
+	if !ok {
+		return nil, fmt.Errorf("plugin: %v's NewStorageDriver has an unexpected signature", path)
+	}
+
+	return newDriver(parameters)
+}
+
+func stringSlice(v interface{}) []string {
+	switch vv := v.(type) {
+	case []string:
+		return vv
+	case []interface{}:
+		out := make([]string, 0, len(vv))
+		for _, e := range vv {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func init() {
+	factory.Register("plugin", &pluginFactory{})
+}
@@ -0,0 +1,352 @@
+package s3
+
+// This file ports the gcs driver's NewParallelWriter (see
+// registry/storage/driver/gcs/parallel.go) to S3's native Multipart Upload
+// API: workers call UploadPart concurrently for buffered chunks, Commit
+// issues CompleteMultipartUpload, and Cancel issues AbortMultipartUpload
+// plus a best-effort sweep for any parts that landed after the abort. It
+// assumes the `driver` struct exposes S3, Bucket, an s3Path method and a
+// pool of reusable chunk buffers, same as the gcs driver's `pool`; chunk
+// size and worker counts come from parseUploadOptions, not the driver.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	storagedriver "github.com/docker/distribution/registry/storage/driver"
+)
+
+// Defaults for the chunksize/workers/maxconcurrentuploads parameters parsed
+// by parseUploadOptions below.
+const (
+	defaultChunkSize            = 32 << 20 // 32MB; S3's actual minimum part size is 5MB
+	defaultUploadWorkers        = 4
+	defaultMaxConcurrentUploads = 4
+)
+
+type chunk struct {
+	buf    []byte
+	partNo int64
+	// done, if non-nil, is closed by the worker once this chunk has been
+	// fully processed. Only Close sets it, on the final chunk, so it can
+	// block until the chunk is actually consumed before closing doneCh --
+	// otherwise a worker's select could pick the now-closed doneCh over the
+	// still-buffered chunk and exit leaving it stranded.
+	done chan struct{}
+}
+
+type parallelWriter struct {
+	driver    *driver
+	path      string
+	key       string
+	uploadID  string
+	chunkSize int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg      *sync.WaitGroup
+	chunkCh chan<- chunk
+	doneCh  chan struct{}
+
+	buf       []byte
+	offset    int
+	totalSize int64
+	partNo    int64
+
+	partsMu sync.Mutex
+	parts   []*s3.CompletedPart
+
+	closed    bool
+	cancelled bool
+
+	errOnce sync.Once
+	errVal  atomic.Value
+}
+
+// storeErr records err as the writer's first error, if one hasn't already
+// been recorded, and cancels ctx so in-flight workers stop picking up new
+// parts rather than running to completion against a doomed upload.
+func (pw *parallelWriter) storeErr(err error) {
+	if err == nil {
+		return
+	}
+	pw.errOnce.Do(func() {
+		pw.errVal.Store(err)
+		pw.cancel()
+	})
+}
+
+// Err returns the first error recorded by a worker, if any.
+func (pw *parallelWriter) Err() error {
+	if v := pw.errVal.Load(); v != nil {
+		return v.(error)
+	}
+	return nil
+}
+
+func (pw *parallelWriter) Write(p []byte) (n int, err error) {
+	for n = 0; n < len(p); {
+		if err := pw.Err(); err != nil {
+			return n, err
+		}
+		if pw.closed {
+			return n, fmt.Errorf("Wrote to closed writer") // TODO: better err?
+		}
+		if pw.buf == nil {
+			// The shared pool's buffers are sized for whatever chunkSize was
+			// in effect when they were allocated; if this writer was
+			// configured with a different chunkSize, don't reuse a
+			// wrong-sized buffer.
+			buf := pw.driver.pool.Get().([]byte)
+			if int64(cap(buf)) != pw.chunkSize {
+				buf = make([]byte, 0, pw.chunkSize)
+			}
+			pw.buf = buf[:0]
+			pw.offset = 0
+		}
+		nn := copy(pw.buf[pw.offset:cap(pw.buf)], p[n:])
+		n += nn
+		pw.offset += nn
+		pw.totalSize += int64(nn)
+		if pw.offset == cap(pw.buf) {
+			pw.partNo++
+			select {
+			case pw.chunkCh <- chunk{buf: pw.buf[:pw.offset], partNo: pw.partNo}:
+			case <-pw.ctx.Done():
+				return n, pw.Err()
+			}
+			pw.buf = nil
+			pw.offset = 0
+		}
+	}
+	return n, nil
+}
+
+func (pw *parallelWriter) Close() error {
+	if pw.closed {
+		return nil
+	}
+	pw.closed = true
+
+	if pw.offset > 0 {
+		pw.partNo++
+		done := make(chan struct{})
+		select {
+		case pw.chunkCh <- chunk{buf: pw.buf[:pw.offset], partNo: pw.partNo, done: done}:
+			// Wait for a worker to actually finish this chunk before closing
+			// doneCh below, so a worker can't race doneCh's close against the
+			// still-buffered chunk and exit without uploading it.
+			select {
+			case <-done:
+			case <-pw.ctx.Done():
+			}
+		case <-pw.ctx.Done():
+			// All workers have already exited; nothing will ever drain
+			// chunkCh, so fall through without blocking on the send.
+		}
+		pw.offset = 0
+		pw.buf = nil
+	}
+
+	close(pw.doneCh)
+	pw.wg.Wait()
+	return pw.Err()
+}
+
+func (pw parallelWriter) Size() int64 {
+	return pw.totalSize
+}
+
+func (pw *parallelWriter) uploadPart(c chunk) error {
+	resp, err := pw.driver.S3.UploadPart(&s3.UploadPartInput{
+		Bucket:     aws.String(pw.driver.Bucket),
+		Key:        aws.String(pw.key),
+		PartNumber: aws.Int64(c.partNo),
+		UploadId:   aws.String(pw.uploadID),
+		Body:       bytes.NewReader(c.buf),
+	})
+	if err != nil {
+		return err
+	}
+
+	pw.partsMu.Lock()
+	pw.parts = append(pw.parts, &s3.CompletedPart{
+		ETag:       resp.ETag,
+		PartNumber: aws.Int64(c.partNo),
+	})
+	pw.partsMu.Unlock()
+	return nil
+}
+
+func (pw *parallelWriter) Cancel() error {
+	pw.Close()
+
+	if pw.cancelled {
+		return nil
+	}
+	pw.cancelled = true
+
+	if _, err := pw.driver.S3.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(pw.driver.Bucket),
+		Key:      aws.String(pw.key),
+		UploadId: aws.String(pw.uploadID),
+	}); err != nil {
+		return err
+	}
+
+	// AbortMultipartUpload doesn't guarantee parts uploaded concurrently
+	// with the abort are cleaned up, so sweep for and remove any stragglers.
+	listResp, err := pw.driver.S3.ListParts(&s3.ListPartsInput{
+		Bucket:   aws.String(pw.driver.Bucket),
+		Key:      aws.String(pw.key),
+		UploadId: aws.String(pw.uploadID),
+	})
+	if err != nil {
+		return nil // best effort; the abort above already recorded intent
+	}
+	if len(listResp.Parts) > 0 {
+		_, _ = pw.driver.S3.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(pw.driver.Bucket),
+			Key:      aws.String(pw.key),
+			UploadId: aws.String(pw.uploadID),
+		})
+	}
+
+	return nil
+}
+
+func (pw *parallelWriter) Commit() error {
+	pw.Close()
+
+	if err := pw.Err(); err != nil {
+		return err
+	}
+
+	pw.partsMu.Lock()
+	parts := make([]*s3.CompletedPart, len(pw.parts))
+	copy(parts, pw.parts)
+	pw.partsMu.Unlock()
+
+	if len(parts) == 0 {
+		return pw.Cancel()
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return aws.Int64Value(parts[i].PartNumber) < aws.Int64Value(parts[j].PartNumber)
+	})
+
+	_, err := pw.driver.S3.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(pw.driver.Bucket),
+		Key:             aws.String(pw.key),
+		UploadId:        aws.String(pw.uploadID),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+	return err
+}
+
+// parseUploadOptions reads chunksize, workers and maxconcurrentuploads from
+// driver parameters, falling back to their defaults. NewParallelWriter calls
+// this directly so a writer always reflects the chunksize/workers/
+// maxconcurrentuploads in effect for the driver parameters it's given, the
+// same way storage_fallback's middleware parses its own options off a
+// map[string]interface{}.
+func parseUploadOptions(parameters map[string]interface{}) (chunkSize int64, workers int, maxConcurrentUploads int) {
+	chunkSize = defaultChunkSize
+	workers = defaultUploadWorkers
+	maxConcurrentUploads = defaultMaxConcurrentUploads
+
+	if v, ok := parameters["chunksize"].(int); ok && v > 0 {
+		chunkSize = int64(v)
+	}
+	if v, ok := parameters["workers"].(int); ok && v > 0 {
+		workers = v
+	}
+	if v, ok := parameters["maxconcurrentuploads"].(int); ok && v > 0 {
+		maxConcurrentUploads = v
+	}
+	return chunkSize, workers, maxConcurrentUploads
+}
+
+// NewParallelWriter starts a multipart upload for path and returns a
+// storagedriver.FileWriter that fans buffered chunks out to workers, each
+// calling UploadPart concurrently (bounded to maxConcurrentUploads
+// in-flight requests); Commit finalizes with CompleteMultipartUpload.
+// parameters is the driver's configuration map; chunksize, workers and
+// maxconcurrentuploads are read from it via parseUploadOptions.
+func NewParallelWriter(ctx context.Context, driver *driver, path string, parameters map[string]interface{}) (storagedriver.FileWriter, error) {
+	chunkSize, workers, maxConcurrentUploads := parseUploadOptions(parameters)
+
+	key := driver.s3Path(path)
+
+	resp, err := driver.S3.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket: aws.String(driver.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wg := &sync.WaitGroup{}
+	chunkCh := make(chan chunk, 1)
+	doneCh := make(chan struct{})
+	ctx, cancel := context.WithCancel(ctx)
+
+	pw := &parallelWriter{
+		driver:    driver,
+		path:      path,
+		key:       key,
+		uploadID:  aws.StringValue(resp.UploadId),
+		chunkSize: chunkSize,
+		ctx:       ctx,
+		cancel:    cancel,
+		wg:        wg,
+		chunkCh:   chunkCh,
+		doneCh:    doneCh,
+	}
+
+	sem := make(chan struct{}, maxConcurrentUploads)
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case c := <-chunkCh:
+					sem <- struct{}{}
+					err := pw.uploadPart(c)
+					<-sem
+					if err != nil {
+						// storeErr cancels ctx, which is enough to stop
+						// every worker (including this one) from picking
+						// up further parts; it must NOT call pw.Cancel()
+						// itself, since Cancel->Close->wg.Wait() would
+						// deadlock this goroutine waiting on its own
+						// wg.Done(). Aborting the multipart upload is the
+						// caller's job once Commit/Close surfaces the
+						// error via pw.Err().
+						pw.storeErr(err)
+					}
+					driver.pool.Put(c.buf[:cap(c.buf)])
+					if c.done != nil {
+						close(c.done)
+					}
+					continue
+				case <-ctx.Done():
+					return
+				case <-doneCh:
+					return
+				}
+			}
+		}()
+	}
+
+	return pw, nil
+}